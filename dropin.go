@@ -0,0 +1,37 @@
+package fflag
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// applyDropins applies every regular file in p.DropinDir through the same
+// parser pipeline as the primary config file, in lexicographic order
+// (the order [os.ReadDir] already returns), so later files override
+// earlier ones. A dropin file that fails to open or parse, or the
+// directory itself failing to read, is aggregated into p.errors with its
+// path as context rather than discarding errors already accumulated from
+// the primary config file.
+func (p *parser) applyDropins() {
+	if p.DropinDir == "" {
+		return
+	}
+	entries, err := os.ReadDir(p.DropinDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		p.errors = append(p.errors, fmt.Errorf("fflag: error reading dropin directory %q: %w", p.DropinDir, err))
+		return
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if err := p.applyFile(filepath.Join(p.DropinDir, e.Name()), false); err != nil {
+			p.errors = append(p.errors, err)
+		}
+	}
+}
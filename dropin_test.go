@@ -0,0 +1,97 @@
+package fflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDropinLayering(t *testing.T) {
+	dir := t.TempDir()
+	dropinDir := filepath.Join(dir, "config.d")
+	if err := os.Mkdir(dropinDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.txt")
+	writeFile(t, path, "name base\nother keep\n")
+	writeFile(t, filepath.Join(dropinDir, "01-first.txt"), "name first\n")
+	writeFile(t, filepath.Join(dropinDir, "02-second.txt"), "name second\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "")
+	other := fs.String("other", "", "")
+	o := NewDefaultOptions()
+	o.Path = path
+	o.DropinDir = dropinDir
+
+	if err := ParseArgs(fs, o, nil); err != nil {
+		t.Fatalf("ParseArgs: %v", err)
+	}
+	if *name != "second" {
+		t.Errorf("name = %q, want %q (later dropin wins)", *name, "second")
+	}
+	if *other != "keep" {
+		t.Errorf("other = %q, want %q (untouched by dropins)", *other, "keep")
+	}
+}
+
+func TestDropinParseErrorAggregatesFileContext(t *testing.T) {
+	dir := t.TempDir()
+	dropinDir := filepath.Join(dir, "config.d")
+	if err := os.Mkdir(dropinDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.txt")
+	writeFile(t, path, "name base\n")
+	badPath := filepath.Join(dropinDir, "01-bad.txt")
+	writeFile(t, badPath, "unknown-flag oops\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	o := NewDefaultOptions()
+	o.Path = path
+	o.DropinDir = dropinDir
+
+	err := ParseArgs(fs, o, nil)
+	if err == nil {
+		t.Fatal("expected an error for an unknown flag in a dropin file, got nil")
+	}
+	if got := err.Error(); !strings.Contains(got, badPath) {
+		t.Errorf("error %q does not mention the dropin file path %q", got, badPath)
+	}
+}
+
+func TestDropinDirReadErrorPreservesPriorErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	writeFile(t, path, "unknown-flag oops\n")
+	// DropinDir points at a regular file, so os.ReadDir fails with ENOTDIR.
+	dropinDir := filepath.Join(dir, "config.d")
+	writeFile(t, dropinDir, "not a directory\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := NewDefaultOptions()
+	o.Path = path
+	o.DropinDir = dropinDir
+
+	err := ParseArgs(fs, o, nil)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	got := err.Error()
+	if !strings.Contains(got, "unknown flag") {
+		t.Errorf("error %q does not mention the primary config file's unknown flag", got)
+	}
+	if !strings.Contains(got, dropinDir) {
+		t.Errorf("error %q does not mention the unreadable dropin directory %q", got, dropinDir)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
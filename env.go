@@ -0,0 +1,51 @@
+package fflag
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// applyEnv sets every flag in fs not already set by the config file
+// (tracked in textFlags) from its corresponding environment variable, if
+// Options.UseEnv is set. It runs after the config file and before
+// command-line arguments are parsed, so those still take precedence.
+//
+// Values are applied via fs.Set rather than f.Value.Set, so that the flag
+// is recorded as actually set (visible to fs.Visit and [checkRequired]),
+// just as if it had been passed on the command line.
+func applyEnv(fs *flag.FlagSet, o *Options, textFlags map[string]textFlagValue) error {
+	if !o.UseEnv {
+		return nil
+	}
+	var errors errs
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := textFlags[f.Name]; ok {
+			return
+		}
+		name := envName(o.EnvPrefix, f.Name)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			errors = append(errors, fmt.Errorf("fflag: error setting flag %q = %q from environment variable %q: %w", f.Name, v, name, err))
+		}
+	})
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// envName builds the environment variable name for a flag: its name
+// uppercased with "-" replaced by "_", prefixed with prefix uppercased and
+// "_". With an empty prefix, the name is returned without either.
+func envName(prefix, flagName string) string {
+	name := strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+	if prefix == "" {
+		return name
+	}
+	return strings.ToUpper(prefix) + "_" + name
+}
@@ -0,0 +1,68 @@
+package fflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrecedenceConfigEnvArgs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	if err := os.WriteFile(path, []byte("name fromconfig\nother fromconfig\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_NAME", "fromenv")
+	t.Setenv("APP_OTHER", "fromenv")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "")
+	other := fs.String("other", "", "")
+	o := NewDefaultOptions()
+	o.Path = path
+	o.UseEnv = true
+	o.EnvPrefix = "APP"
+
+	if err := ParseArgs(fs, o, []string{"-name", "fromargs"}); err != nil {
+		t.Fatalf("ParseArgs: %v", err)
+	}
+	if *name != "fromargs" {
+		t.Errorf("name = %q, want %q (args override env and config)", *name, "fromargs")
+	}
+	if *other != "fromconfig" {
+		t.Errorf("other = %q, want %q (config takes precedence; env only fills gaps)", *other, "fromconfig")
+	}
+}
+
+func TestRequiredSatisfiedByEnv(t *testing.T) {
+	t.Setenv("APP_NAME", "fromenv")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "")
+	Required(fs, "name")
+	o := NewDefaultOptions()
+	o.Path = filepath.Join(t.TempDir(), "missing.txt")
+	o.UseEnv = true
+	o.EnvPrefix = "APP"
+
+	if err := ParseArgs(fs, o, nil); err != nil {
+		t.Fatalf("ParseArgs: %v", err)
+	}
+	if *name != "fromenv" {
+		t.Errorf("name = %q, want %q", *name, "fromenv")
+	}
+}
+
+func TestRequiredMissing(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	Required(fs, "name")
+	o := NewDefaultOptions()
+	o.Path = filepath.Join(t.TempDir(), "missing.txt")
+
+	err := ParseArgs(fs, o, nil)
+	if err == nil {
+		t.Fatal("expected an error for a missing required flag, got nil")
+	}
+}
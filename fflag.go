@@ -9,10 +9,24 @@
 // Comments begin with any of these: # ; //
 //
 // Leading and trailing whitespace is ignored on each line, key and value.
+//
+// # Other formats
+//
+// The config file format is pluggable via [Options.Parser] and
+// [ConfigFileParser]. Built-in parsers are provided for .env ([EnvParser]),
+// JSON ([JSONParser]), YAML ([YAMLParser]) and TOML ([TOMLParser]) files;
+// set [Options.AutoDetectFormat] to pick one of these by file extension.
+//
+// # Precedence
+//
+// Flags start at their declared default. The config file is applied first; any
+// flag it does not set falls back to its environment variable when
+// [Options.UseEnv] is set. Command-line arguments are parsed last and
+// always win, regardless of whether the config file or the environment
+// set a flag.
 package fflag
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
@@ -37,6 +51,50 @@ type Options struct {
 	// WriteConfigFlagName is the name of the flag that causes the current
 	// configuration to be printed.
 	WriteConfigFlagName string
+
+	// Parser decodes the config file into name/value pairs.
+	//
+	// If nil, the format is chosen by AutoDetectFormat or otherwise
+	// defaults to [FlagParser].
+	Parser ConfigFileParser
+
+	// AutoDetectFormat picks a built-in [ConfigFileParser] based on the
+	// config file's extension (.env, .json, .yaml/.yml, .toml) when Parser
+	// is not set. Unrecognized extensions fall back to [FlagParser].
+	AutoDetectFormat bool
+
+	// UseEnv looks up an environment variable for every flag not already
+	// set by the config file, before command-line arguments are parsed.
+	//
+	// The variable name is the flag name uppercased with "-" replaced by
+	// "_", prefixed with the uppercased EnvPrefix and an underscore unless
+	// EnvPrefix is empty.
+	UseEnv bool
+
+	// EnvPrefix is prepended to the environment variable name looked up
+	// for each flag when UseEnv is set, e.g. "MYAPP" turns flag
+	// "listen-addr" into "MYAPP_LISTEN_ADDR".
+	EnvPrefix string
+
+	// DropinDir is a directory of additional config files applied after
+	// Path, in lexicographic order, through the same parser pipeline.
+	// Later files override earlier ones and Path itself acts as the base.
+	//
+	// If DropinDir does not exist, it is silently ignored, mirroring the
+	// behavior of a missing Path.
+	DropinDir string
+
+	// ErrorHandling controls what ParseArgs does when the config file,
+	// environment or required-flag checks produce an error.
+	//
+	// ContinueOnError (the default) returns the error. ExitOnError prints
+	// it to fs.Output() and calls [os.Exit](2). PanicOnError panics.
+	ErrorHandling flag.ErrorHandling
+
+	// WriteConfigFormat selects the [ConfigFileWriter] used when the
+	// write-config flag is invoked: "fflag" (the default, [FlagWriter]),
+	// "env" ([EnvWriter]) or "json" ([JSONWriter]).
+	WriteConfigFormat string
 }
 
 // NewDefaultOptions returns default options for use in [Parse].
@@ -53,12 +111,14 @@ func NewDefaultOptions() *Options {
 }
 
 // WriteFlagSetConfig writes a configuration file to w including both default
-// and currently set values (should they differ).
+// and currently set values (should they differ). Values that would not
+// round-trip through [FlagParser] unquoted (because they contain whitespace,
+// newlines or start with a comment character or "=") are double-quoted with escape
+// expansion, as [FlagParser] understands.
+//
+// It implements [ConfigFileWriter] and is also available as [FlagWriter].
 func WriteFlagSetConfig(w io.Writer, fs *flag.FlagSet, ignoreFlags ...string) {
-	flags := make(map[string]struct{}, len(ignoreFlags))
-	for i := range ignoreFlags {
-		flags[ignoreFlags[i]] = struct{}{}
-	}
+	flags := ignoreSet(ignoreFlags)
 	fmt.Fprint(w, multilineComment(`fflag file syntax:
 
   flag-name flag-value
@@ -81,7 +141,7 @@ Leading and trailing whitespace is ignored on each line, key and value.`, 1))
 			f.DefValue,
 		)
 		if f.DefValue != f.Value.String() {
-			fmt.Fprintf(w, "%s %s\n", f.Name, f.Value)
+			fmt.Fprintf(w, "%s %s\n", f.Name, quoteIfNeeded(f.Value.String()))
 		}
 		fmt.Fprintln(w)
 	})
@@ -92,27 +152,29 @@ func multilineComment(s string, indent int) string {
 	return "#" + ind + strings.ReplaceAll(s, "\n", "\n#"+ind)
 }
 
-// ErrWriteConfig is returned by [Parse] after the current configuration has been
-// to written to [os.Stdout].
-var ErrWriteConfig = errors.New("wrote configuration to stdout")
+// ErrWriteConfig is returned by [Parse] after the current configuration has
+// been written to [os.Stdout] or to the path given to the write-config
+// flag.
+var ErrWriteConfig = errors.New("wrote configuration")
 
 // Parse a config file using [os.Args] into an existing [flag.FlagSet] before parsing the FlagSet itself.
 //
-// Returns [ErrWriteConfig] if the configuration was written to stdout as requested.
+// Returns [ErrWriteConfig] if the configuration was written as requested.
 func Parse(fs *flag.FlagSet, o *Options) error {
 	return ParseArgs(fs, o, os.Args[1:])
 }
 
 // ParseArgs parses a config file using given arguments into an existing [flag.FlagSet] before parsing the FlagSet itself.
 //
-// Returns [ErrWriteConfig] if the configuration was written to stdout as requested.
+// Returns [ErrWriteConfig] if the configuration was written as requested.
 func ParseArgs(fs *flag.FlagSet, o *Options, args []string) error {
 	if o == nil {
 		o = NewDefaultOptions()
 	}
 	fs.String(o.ConfigFlagName, o.Path, "path to config file")
+	wc := &writeConfigTarget{}
 	if o.WriteConfigFlagName != "" {
-		fs.Bool(o.WriteConfigFlagName, false, "write configuration to stdout and exit")
+		fs.Var(wc, o.WriteConfigFlagName, "write configuration to stdout, or to the given file path, and exit")
 	}
 	configPath := getFlagConfigPath(o.ConfigFlagName)
 	fileMustExist := false
@@ -121,27 +183,59 @@ func ParseArgs(fs *flag.FlagSet, o *Options, args []string) error {
 		fileMustExist = true
 		o.Path = configPath
 	}
+	defer clearRequired(fs)
 	p := &parser{
 		fileMustExist: fileMustExist,
 		fs:            fs,
 		Options:       o,
-		textFlags:     map[string]string{},
+		textFlags:     map[string]textFlagValue{},
 	}
-	err := p.parse()
-	if err != nil {
-		return err
+	if p.Parser == nil && p.AutoDetectFormat {
+		p.Parser = detectParser(p.Path)
+	}
+	if p.Parser == nil {
+		p.Parser = FlagParser
+	}
+	if err := p.parse(); err != nil {
+		return o.handleError(fs, err)
 	}
-	err = fs.Parse(args)
+	if err := applyEnv(fs, o, p.textFlags); err != nil {
+		return o.handleError(fs, err)
+	}
+	err := fs.Parse(args)
 	if err != nil && !errors.Is(err, ErrWriteConfig) {
 		return err
 	}
-	if getFlagWriteConfig(o.WriteConfigFlagName) {
-		WriteFlagSetConfig(os.Stdout, fs, o.ConfigFlagName, o.WriteConfigFlagName)
+	if wc.path != "" {
+		w, closeW, err := wc.writer()
+		if err != nil {
+			return err
+		}
+		defer closeW()
+		writerForFormat(o.WriteConfigFormat)(w, fs, o.ConfigFlagName, o.WriteConfigFlagName)
 		return ErrWriteConfig
 	}
+	if err := checkRequired(fs, p.textFlags); err != nil {
+		return o.handleError(fs, err)
+	}
 	return nil
 }
 
+// handleError applies o.ErrorHandling to a non-nil error from the config
+// file, environment or required-flag checks.
+func (o *Options) handleError(fs *flag.FlagSet, err error) error {
+	switch o.ErrorHandling {
+	case flag.ExitOnError:
+		fmt.Fprintln(fs.Output(), err)
+		os.Exit(2)
+		return nil
+	case flag.PanicOnError:
+		panic(err)
+	default:
+		return err
+	}
+}
+
 func getFlagConfigPath(configFlagName string) string {
 	f := flag.NewFlagSet(configFlagName, flag.ContinueOnError)
 	// don't care about -h here and errors are handled by p.visitFlag
@@ -152,30 +246,28 @@ func getFlagConfigPath(configFlagName string) string {
 	return configPath
 }
 
-func getFlagWriteConfig(writeConfigFlagName string) bool {
-	f := flag.NewFlagSet(writeConfigFlagName, flag.ContinueOnError)
-	// don't care about -h here and errors are handled by p.visitFlag
-	f.SetOutput(io.Discard)
-	var writeConfig bool
-	f.BoolVar(&writeConfig, writeConfigFlagName, false, "write configuration to stdout")
-	f.Parse(os.Args[1:])
-	return writeConfig
+// textFlagValue records a config-file-supplied flag value together with
+// the path of the file it came from, so errors can point at the right
+// file even when DropinDir is in play.
+type textFlagValue struct {
+	value string
+	path  string
 }
 
 type parser struct {
 	*Options
-	lineNo int
-	fs     *flag.FlagSet
+	fs *flag.FlagSet
 	// true when a specific config was requested via -config flag
 	fileMustExist bool
-	textFlags     map[string]string
+	textFlags     map[string]textFlagValue
 	errors        errs
 }
 
 func (p *parser) parse() error {
-	if err := p.scanTextFlags(); err != nil {
+	if err := p.applyFile(p.Path, p.fileMustExist); err != nil {
 		return err
 	}
+	p.applyDropins()
 	p.fs.VisitAll(p.visitFlag)
 	if len(p.errors) > 0 {
 		return p.errors
@@ -184,72 +276,44 @@ func (p *parser) parse() error {
 }
 
 func (p *parser) visitFlag(f *flag.Flag) {
-	v, ok := p.textFlags[f.Name]
+	tv, ok := p.textFlags[f.Name]
 	if !ok {
 		return
 	}
-	if err := f.Value.Set(v); err != nil {
-		err = fmt.Errorf("fflag: error setting flag %q = %q from config file %q: %w", f.Name, v, p.Path, err)
+	if err := f.Value.Set(tv.value); err != nil {
+		err = fmt.Errorf("fflag: error setting flag %q = %q from config file %q: %w", f.Name, tv.value, tv.path, err)
 		p.errors = append(p.errors, err)
 	}
 }
 
-func (p *parser) scanTextFlags() error {
-	f, err := os.Open(p.Path)
+// applyFile runs path through p.Parser, funnelling every name/value pair it
+// reports through a set closure that records path as their origin. Any
+// error returned by the parser is aggregated into p.errors with path as
+// context; applyFile itself only returns an error when path could not be
+// opened at all, leaving the caller to decide whether that aborts parsing
+// (the primary config file) or is itself aggregated (dropins).
+func (p *parser) applyFile(path string, mustExist bool) error {
+	f, err := os.Open(path)
 	if err != nil {
-		if !p.fileMustExist && errors.Is(err, os.ErrNotExist) {
+		if !mustExist && errors.Is(err, os.ErrNotExist) {
 			return nil
 		}
-		return fmt.Errorf("fflag: error reading -%s=%q: %w", p.ConfigFlagName, p.Path, err)
+		return fmt.Errorf("fflag: error reading -%s=%q: %w", p.ConfigFlagName, path, err)
 	}
 	defer f.Close()
-	sc := bufio.NewScanner(f)
-	for sc.Scan() {
-		err = p.scanLine(sc)
-		if err != nil {
-			p.errors = append(p.errors, err)
+	set := func(name, value string) error {
+		p.textFlags[name] = textFlagValue{value: value, path: path}
+		if p.fs.Lookup(name) == nil {
+			return fmt.Errorf("contains unknown flag name %q", name)
 		}
-	}
-	return sc.Err()
-}
-
-func (p *parser) scanLine(sc *bufio.Scanner) error {
-	p.lineNo++
-	k, v := parseLine(sc.Text())
-	if k == "" {
 		return nil
 	}
-	p.textFlags[k] = v
-	if fl := p.fs.Lookup(k); fl == nil {
-		return fmt.Errorf("fflag: config file %q line %d contains unknown flag name %q", p.Path, p.lineNo, k)
+	if err := p.Parser(f, set); err != nil {
+		p.errors = append(p.errors, fmt.Errorf("fflag: config file %q: %w", path, err))
 	}
 	return nil
 }
 
-func parseLine(line string) (k, v string) {
-	line = strings.TrimSpace(line)
-	if isComment(line) {
-		return "", ""
-	}
-	parts := strings.SplitN(line, " ", 2)
-	k = parts[0]
-	if len(parts) == 2 {
-		v = strings.TrimSpace(parts[1])
-	}
-	return
-}
-
-func isComment(line string) bool {
-	if len(line) == 0 {
-		return true
-	}
-	if len(line) >= 2 && line[:2] == "//" {
-		return true
-	}
-	b := line[0]
-	return b == ';' || b == '#' || b == '\''
-}
-
 type errs []error
 
 func (e errs) Error() string {
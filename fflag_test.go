@@ -0,0 +1,57 @@
+package fflag
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleErrorPanicOnError(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	Required(fs, "name")
+	o := NewDefaultOptions()
+	o.Path = filepath.Join(t.TempDir(), "missing.txt")
+	o.ErrorHandling = flag.PanicOnError
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected ParseArgs to panic, it did not")
+		}
+	}()
+	ParseArgs(fs, o, nil)
+	t.Fatal("unreachable: ParseArgs returned instead of panicking")
+}
+
+// TestHandleErrorExitOnError exercises the ExitOnError branch of
+// handleError in a subprocess, since it calls os.Exit(2) directly. See
+// https://pkg.go.dev/testing#hdr-Subtests_and_Sub_benchmarks for the
+// pattern: the subprocess is this same test binary, re-invoked with an
+// env var that makes this test run the exiting code instead of
+// the assertions.
+func TestHandleErrorExitOnError(t *testing.T) {
+	if os.Getenv("FFLAG_TEST_EXIT_ON_ERROR") == "1" {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("name", "", "")
+		Required(fs, "name")
+		o := NewDefaultOptions()
+		o.Path = filepath.Join(t.TempDir(), "missing.txt")
+		o.ErrorHandling = flag.ExitOnError
+		ParseArgs(fs, o, nil)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestHandleErrorExitOnError")
+	cmd.Env = append(os.Environ(), "FFLAG_TEST_EXIT_ON_ERROR=1")
+	err := cmd.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected subprocess to exit with an error, got %v", err)
+	}
+	if got := exitErr.ExitCode(); got != 2 {
+		t.Errorf("exit code = %d, want 2", got)
+	}
+}
@@ -0,0 +1,212 @@
+package fflag
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// detectParser picks a built-in [ConfigFileParser] based on path's
+// extension, or returns nil if the extension is not recognized.
+func detectParser(path string) ConfigFileParser {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".env":
+		return EnvParser
+	case ".json":
+		return JSONParser
+	case ".yaml", ".yml":
+		return YAMLParser
+	case ".toml":
+		return TOMLParser
+	default:
+		return nil
+	}
+}
+
+// EnvParser implements .env-style config files: VAR=value pairs, one per
+// line, with optional double-quoted escape expansion or single-quoted
+// literal values. It is an alias for [FlagParser], whose default syntax
+// already tokenizes lines the same way.
+var EnvParser ConfigFileParser = FlagParser
+
+// JSONParser reads a config file containing a single flat JSON object, e.g.
+//
+//	{"flag-name": "flag-value", "verbose": true}
+//
+// Object values must be scalars (string, number, bool or null); nested
+// objects and arrays are rejected.
+var JSONParser ConfigFileParser = func(r io.Reader, set func(name, value string) error) error {
+	var m map[string]any
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&m); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	var errors errs
+	for k, v := range m {
+		s, err := jsonScalarString(v)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %w", k, err))
+			continue
+		}
+		if err := set(k, s); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+func jsonScalarString(v any) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case bool, float64:
+		return fmt.Sprint(t), nil
+	default:
+		return "", fmt.Errorf("unsupported JSON value type %T, expected a scalar", v)
+	}
+}
+
+// YAMLParser reads a config file containing a single flat YAML mapping,
+// e.g.
+//
+//	flag-name: flag-value
+//	verbose: "true" # inline comments are allowed
+//
+// YAMLParser is NOT a general-purpose YAML parser: it only understands
+// one "key: value" pair per line. Nested mappings, lists, anchors,
+// multi-line scalars and multi-document files are all rejected; a value
+// spanning more than one visual line must be double-quoted with \n
+// escapes instead, as [FlagParser] expects.
+var YAMLParser ConfigFileParser = func(r io.Reader, set func(name, value string) error) error {
+	var errors errs
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	started := false
+	for sc.Scan() {
+		lineNo++
+		raw := sc.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "---" && !started {
+			started = true
+			continue
+		}
+		started = true
+		if line == "---" || raw[0] == ' ' || raw[0] == '\t' || strings.HasPrefix(line, "-") {
+			errors = append(errors, fmt.Errorf("line %d: only a single flat YAML mapping is supported", lineNo))
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			errors = append(errors, fmt.Errorf("line %d: expected \"key: value\"", lineNo))
+			continue
+		}
+		k = strings.TrimSpace(k)
+		v = stripInlineComment(strings.TrimSpace(v))
+		if v != "" && strings.ContainsRune("&*|>[{", rune(v[0])) {
+			errors = append(errors, fmt.Errorf("line %d: YAML anchors, aliases, block scalars and flow collections are not supported", lineNo))
+			continue
+		}
+		if err := set(k, unquote(strings.TrimSpace(v))); err != nil {
+			errors = append(errors, fmt.Errorf("line %d: %w", lineNo, err))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// TOMLParser reads a config file containing flat TOML key/value pairs,
+// e.g.
+//
+//	flag-name = "flag-value"
+//	verbose = true # inline comments are allowed
+//
+// TOMLParser is NOT a general-purpose TOML parser: it only understands
+// one "key = value" pair per line in the top-level table. Tables
+// (`[section]`), arrays, inline tables and multi-line strings are all
+// rejected.
+var TOMLParser ConfigFileParser = func(r io.Reader, set func(name, value string) error) error {
+	var errors errs
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			errors = append(errors, fmt.Errorf("line %d: TOML tables are not supported, use flat key = value pairs", lineNo))
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			errors = append(errors, fmt.Errorf("line %d: expected \"key = value\"", lineNo))
+			continue
+		}
+		k = strings.TrimSpace(k)
+		v = stripInlineComment(strings.TrimSpace(v))
+		if strings.HasPrefix(v, "[") || strings.HasPrefix(v, "{") || strings.HasPrefix(v, `"""`) || strings.HasPrefix(v, "'''") {
+			errors = append(errors, fmt.Errorf("line %d: TOML arrays, inline tables and multi-line strings are not supported", lineNo))
+			continue
+		}
+		if err := set(k, unquote(strings.TrimSpace(v))); err != nil {
+			errors = append(errors, fmt.Errorf("line %d: %w", lineNo, err))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// stripInlineComment truncates s at the first unquoted '#', so that a
+// trailing comment on a YAML/TOML line doesn't get swallowed into the
+// value. '#' is the only comment leader either format recognizes.
+// Quoting rules match [unquote]: a '#' inside a matching pair of single
+// or double quotes is left alone.
+func stripInlineComment(s string) string {
+	inDouble, inSingle := false, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case inDouble:
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			} else if s[i] == '"' {
+				inDouble = false
+			}
+		case inSingle:
+			if s[i] == '\'' {
+				inSingle = false
+			}
+		case s[i] == '"':
+			inDouble = true
+		case s[i] == '\'':
+			inSingle = true
+		case s[i] == '#':
+			return strings.TrimRight(s[:i], " \t")
+		}
+	}
+	return s
+}
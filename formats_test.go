@@ -0,0 +1,109 @@
+package fflag
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseAll(t *testing.T, p ConfigFileParser, src string) (map[string]string, error) {
+	t.Helper()
+	got := map[string]string{}
+	err := p(strings.NewReader(src), func(name, value string) error {
+		got[name] = value
+		return nil
+	})
+	return got, err
+}
+
+func TestJSONParser(t *testing.T) {
+	got, err := parseAll(t, JSONParser, `{"name": "alice", "port": 8080, "verbose": true}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"name": "alice", "port": "8080", "verbose": "true"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestJSONParserRejectsNestedValues(t *testing.T) {
+	_, err := parseAll(t, JSONParser, `{"name": {"nested": true}}`)
+	if err == nil {
+		t.Fatal("expected an error for a nested JSON value, got nil")
+	}
+}
+
+func TestYAMLParser(t *testing.T) {
+	got, err := parseAll(t, YAMLParser, "port: 8080 # the listen port\nname: \"alice\"\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["port"] != "8080" {
+		t.Errorf(`got["port"] = %q, want "8080" (inline comment should be stripped)`, got["port"])
+	}
+	if got["name"] != "alice" {
+		t.Errorf(`got["name"] = %q, want "alice"`, got["name"])
+	}
+}
+
+func TestYAMLParserKeepsQuotedHash(t *testing.T) {
+	got, err := parseAll(t, YAMLParser, `name: "alice # not a comment"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "alice # not a comment"; got["name"] != want {
+		t.Errorf("got[\"name\"] = %q, want %q", got["name"], want)
+	}
+}
+
+func TestYAMLParserKeepsSemicolon(t *testing.T) {
+	got, err := parseAll(t, YAMLParser, "greeting: hi;there\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "hi;there"; got["greeting"] != want {
+		t.Errorf(`got["greeting"] = %q, want %q (";" is not a YAML comment leader)`, got["greeting"], want)
+	}
+}
+
+func TestTOMLParser(t *testing.T) {
+	got, err := parseAll(t, TOMLParser, "port = 8080 # the listen port\nname = \"alice\"\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["port"] != "8080" {
+		t.Errorf(`got["port"] = %q, want "8080" (inline comment should be stripped)`, got["port"])
+	}
+	if got["name"] != "alice" {
+		t.Errorf(`got["name"] = %q, want "alice"`, got["name"])
+	}
+}
+
+func TestTOMLParserRejectsTables(t *testing.T) {
+	_, err := parseAll(t, TOMLParser, "[section]\nname = \"alice\"\n")
+	if err == nil {
+		t.Fatal("expected an error for a TOML table header, got nil")
+	}
+}
+
+func TestTOMLParserKeepsSemicolon(t *testing.T) {
+	got, err := parseAll(t, TOMLParser, "greeting = hi;there\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "hi;there"; got["greeting"] != want {
+		t.Errorf(`got["greeting"] = %q, want %q (";" is not a TOML comment leader)`, got["greeting"], want)
+	}
+}
+
+func TestEnvParser(t *testing.T) {
+	got, err := parseAll(t, EnvParser, "NAME=alice\nPORT = 8080\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["NAME"] != "alice" || got["PORT"] != "8080" {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,177 @@
+package fflag
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ConfigFileParser reads name/value pairs out of r and reports each one to
+// set. It is used to decode a config file into flag assignments before they
+// are funnelled through [flag.FlagSet] validation.
+//
+// If set returns an error for a given pair (for example because the name
+// does not match a known flag), the parser should record it, continue
+// reading the rest of r, and return an aggregate error once done rather than
+// aborting on the first failure.
+type ConfigFileParser func(r io.Reader, set func(name, value string) error) error
+
+// FlagParser implements fflag's default config file syntax:
+//
+//	flag-name flag-value
+//	flag-name = flag-value
+//
+// flag-value may be double-quoted, in which case \n, \t, \r, \\ and \" are
+// expanded, or single-quoted, in which case it is taken literally. Comments
+// begin with any of these: # ' ; //. Leading and trailing whitespace is
+// ignored on each line, key and value.
+var FlagParser ConfigFileParser = func(r io.Reader, set func(name, value string) error) error {
+	var errors errs
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		k, v := parseLine(sc.Text())
+		if k == "" {
+			continue
+		}
+		if err := set(k, v); err != nil {
+			errors = append(errors, fmt.Errorf("line %d: %w", lineNo, err))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+func parseLine(line string) (k, v string) {
+	line = strings.TrimSpace(line)
+	if isComment(line) {
+		return "", ""
+	}
+	k, rest, ok := splitKeyValue(line)
+	if !ok {
+		return k, ""
+	}
+	return k, unquote(strings.TrimSpace(rest))
+}
+
+// splitKeyValue splits line into its key and the remainder at the first
+// whitespace or "=", accepting "key value", "key=value" and "key = value"
+// forms. The separator and leading whitespace are removed from the
+// remainder; trailing whitespace is kept.
+func splitKeyValue(line string) (key, rest string, ok bool) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, "", false
+	}
+	key = line[:i]
+	rest = strings.TrimLeft(line[i:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+	return key, strings.TrimLeft(rest, " \t"), true
+}
+
+// unquote strips a single matching pair of surrounding quotes from s, if
+// present. Double-quoted values have \n, \t, \r, \\ and \" expanded;
+// single-quoted values are returned literally.
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		return expandEscapes(s[1 : len(s)-1])
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1]
+	default:
+		return s
+	}
+}
+
+// expandEscapes expands \n, \t, \r, \\ and \" within s; any other
+// backslash escape is left untouched.
+func expandEscapes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '\\':
+				sb.WriteByte('\\')
+			case '"':
+				sb.WriteByte('"')
+			default:
+				sb.WriteByte(s[i])
+				sb.WriteByte(s[i+1])
+			}
+			i++
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// quoteIfNeeded double-quotes s, escaping \n, \t, \r, \\ and \", if it
+// would not otherwise round-trip through [parseLine]: because it contains
+// whitespace or a double quote, or starts with a comment character or "=".
+// An empty s is returned unchanged.
+func quoteIfNeeded(s string) string {
+	if !needsQuote(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return false
+	}
+	if isComment(s) || strings.HasPrefix(s, "=") {
+		return true
+	}
+	return strings.ContainsAny(s, " \t\n\r\"")
+}
+
+func isComment(line string) bool {
+	if len(line) == 0 {
+		return true
+	}
+	if len(line) >= 2 && line[:2] == "//" {
+		return true
+	}
+	b := line[0]
+	return b == ';' || b == '#' || b == '\''
+}
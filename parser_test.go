@@ -0,0 +1,63 @@
+package fflag
+
+import "testing"
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line    string
+		wantKey string
+		wantVal string
+	}{
+		{"name value", "name", "value"},
+		{"name=value", "name", "value"},
+		{"name = value", "name", "value"},
+		{"name  value", "name", "value"},
+		{`name = "line one\nline two"`, "name", "line one\nline two"},
+		{`name 'literal \n value'`, "name", `literal \n value`},
+		{`name "quote: \" end"`, "name", `quote: " end`},
+		{"name", "name", ""},
+		{"# a comment", "", ""},
+		{"; a comment", "", ""},
+		{"// a comment", "", ""},
+		{"", "", ""},
+		{"   ", "", ""},
+	}
+	for _, c := range cases {
+		k, v := parseLine(c.line)
+		if k != c.wantKey || v != c.wantVal {
+			t.Errorf("parseLine(%q) = (%q, %q), want (%q, %q)", c.line, k, v, c.wantKey, c.wantVal)
+		}
+	}
+}
+
+func TestQuoteUnquoteRoundTrip(t *testing.T) {
+	values := []string{
+		"plain",
+		"hello world with # inside",
+		"line one\nline two",
+		"a \\nb",
+		`C:\temp`,
+		`has "quotes" inside`,
+		"trailing space ",
+		" leading space",
+		"tab\tvalue",
+	}
+	for _, v := range values {
+		quoted := quoteIfNeeded(v)
+		k, got := parseLine("name " + quoted)
+		if k != "name" {
+			t.Fatalf("quoteIfNeeded(%q) = %q, which parseLine no longer reads as a single value: key=%q", v, quoted, k)
+		}
+		if got != v {
+			t.Errorf("round-trip mismatch: quoteIfNeeded(%q) = %q, parsed back as %q", v, quoted, got)
+		}
+	}
+}
+
+func TestQuoteIfNeededLeavesPlainValuesAlone(t *testing.T) {
+	for _, v := range []string{"plain", "with-dashes", "123"} {
+		if got := quoteIfNeeded(v); got != v {
+			t.Errorf("quoteIfNeeded(%q) = %q, want unchanged", v, got)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package fflag
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+)
+
+var (
+	requiredMu    sync.Mutex
+	requiredFlags = map[*flag.FlagSet]map[string]struct{}{}
+)
+
+// Required marks the named flags in fs as required. [ParseArgs] returns an
+// error if any of them was not set by the config file, an environment
+// variable or the command line by the time parsing finishes.
+func Required(fs *flag.FlagSet, names ...string) {
+	requiredMu.Lock()
+	defer requiredMu.Unlock()
+	set := requiredFlags[fs]
+	if set == nil {
+		set = map[string]struct{}{}
+		requiredFlags[fs] = set
+	}
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+}
+
+// clearRequired removes fs's entry from the required-flags registry, so the
+// registry does not keep fs alive. A FlagSet parsed again has its required
+// names checked only if they are registered again via [Required]. [ParseArgs] defers
+// this call so it runs regardless of which error path, if any, ParseArgs
+// returns through.
+func clearRequired(fs *flag.FlagSet) {
+	requiredMu.Lock()
+	delete(requiredFlags, fs)
+	requiredMu.Unlock()
+}
+
+// checkRequired verifies every flag registered via [Required] for fs was
+// set, either on the command line, via an environment variable (both
+// visible through fs.Visit, see [applyEnv]) or via textFlags from the
+// config file.
+func checkRequired(fs *flag.FlagSet, textFlags map[string]textFlagValue) error {
+	requiredMu.Lock()
+	names := requiredFlags[fs]
+	requiredMu.Unlock()
+	if len(names) == 0 {
+		return nil
+	}
+	setByArgs := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setByArgs[f.Name] = true })
+	var missing errs
+	for name := range names {
+		if setByArgs[name] {
+			continue
+		}
+		if _, ok := textFlags[name]; ok {
+			continue
+		}
+		missing = append(missing, fmt.Errorf("fflag: required flag %q was not set", name))
+	}
+	if len(missing) > 0 {
+		return missing
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package fflag
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+// TestRequiredClearedOnEarlyError verifies that ParseArgs clears fs's entry
+// in the required-flags registry even when it returns before checkRequired
+// runs, e.g. because the config file itself failed to parse.
+func TestRequiredClearedOnEarlyError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.txt")
+	writeFile(t, path, "unknown-flag oops\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	Required(fs, "name")
+	o := NewDefaultOptions()
+	o.Path = path
+
+	if err := ParseArgs(fs, o, nil); err == nil {
+		t.Fatal("expected an error for the unknown flag in the config file, got nil")
+	}
+
+	requiredMu.Lock()
+	_, leaked := requiredFlags[fs]
+	requiredMu.Unlock()
+	if leaked {
+		t.Error("requiredFlags still holds fs after ParseArgs returned early, wanted it cleared")
+	}
+}
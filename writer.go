@@ -0,0 +1,118 @@
+package fflag
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ConfigFileWriter serializes fs's current flag values to w in a format a
+// matching [ConfigFileParser] can read back. ignoreFlags lists flag names
+// to omit, typically the flags fflag itself adds to fs.
+type ConfigFileWriter func(w io.Writer, fs *flag.FlagSet, ignoreFlags ...string)
+
+// FlagWriter is an alias for [WriteFlagSetConfig], fflag's default
+// round-trippable config file format.
+var FlagWriter ConfigFileWriter = WriteFlagSetConfig
+
+// EnvWriter writes fs's current flag values as .env-style NAME=value
+// lines, quoting values as necessary to round-trip through [EnvParser].
+// Only flags whose value differs from their default are written.
+var EnvWriter ConfigFileWriter = func(w io.Writer, fs *flag.FlagSet, ignoreFlags ...string) {
+	ignore := ignoreSet(ignoreFlags)
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, skip := ignore[f.Name]; skip {
+			return
+		}
+		if f.DefValue == f.Value.String() {
+			return
+		}
+		fmt.Fprintf(w, "%s=%s\n", f.Name, quoteIfNeeded(f.Value.String()))
+	})
+}
+
+// JSONWriter writes fs's current flag values as a flat JSON object, read
+// back by [JSONParser]. Only flags whose value differs from their default
+// are written.
+var JSONWriter ConfigFileWriter = func(w io.Writer, fs *flag.FlagSet, ignoreFlags ...string) {
+	ignore := ignoreSet(ignoreFlags)
+	m := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, skip := ignore[f.Name]; skip {
+			return
+		}
+		if f.DefValue == f.Value.String() {
+			return
+		}
+		m[f.Name] = f.Value.String()
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(m)
+}
+
+// writerForFormat returns the built-in [ConfigFileWriter] named by format,
+// falling back to [FlagWriter] for "" or an unrecognized name.
+func writerForFormat(format string) ConfigFileWriter {
+	switch format {
+	case "env":
+		return EnvWriter
+	case "json":
+		return JSONWriter
+	default:
+		return FlagWriter
+	}
+}
+
+func ignoreSet(names []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		m[n] = struct{}{}
+	}
+	return m
+}
+
+// writeConfigTarget is the [flag.Value] backing the write-config flag. It
+// also implements the unexported boolFlag interface so "-write-config"
+// alone still means "write to stdout", while "-write-config=path" writes
+// to that file.
+type writeConfigTarget struct {
+	path string
+}
+
+func (t *writeConfigTarget) String() string {
+	if t == nil {
+		return ""
+	}
+	return t.path
+}
+
+func (t *writeConfigTarget) Set(s string) error {
+	switch s {
+	case "true":
+		s = "-"
+	case "false":
+		// "-write-config=false" is the standard bool-flag idiom for "don't
+		// enable this", not a request to write to a file named "false".
+		s = ""
+	}
+	t.path = s
+	return nil
+}
+
+func (t *writeConfigTarget) IsBoolFlag() bool { return true }
+
+// writer opens the destination for t, returning [os.Stdout] unclosed for
+// "-" or a newly created file otherwise.
+func (t *writeConfigTarget) writer() (io.Writer, func() error, error) {
+	if t.path == "-" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(t.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fflag: error writing configuration to %q: %w", t.path, err)
+	}
+	return f, f.Close, nil
+}
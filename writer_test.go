@@ -0,0 +1,146 @@
+package fflag
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteFlagSetConfigRoundTrip(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "the name")
+	*name = "alice # not a comment"
+	fs.Int("port", 8080, "the port")
+
+	var buf bytes.Buffer
+	WriteFlagSetConfig(&buf, fs)
+
+	got, err := parseAll(t, FlagParser, buf.String())
+	if err != nil {
+		t.Fatalf("re-parsing written config: %v", err)
+	}
+	if got["name"] != "alice # not a comment" {
+		t.Errorf(`got["name"] = %q, want %q`, got["name"], "alice # not a comment")
+	}
+}
+
+func TestWriteFlagSetConfigOnlyWritesChangedValues(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	port := fs.Int("port", 8080, "the port")
+	*port = 9090
+	fs.String("name", "alice", "the name")
+
+	var buf bytes.Buffer
+	WriteFlagSetConfig(&buf, fs)
+
+	got, err := parseAll(t, FlagParser, buf.String())
+	if err != nil {
+		t.Fatalf("re-parsing written config: %v", err)
+	}
+	if got["port"] != "9090" {
+		t.Errorf(`got["port"] = %q, want "9090"`, got["port"])
+	}
+	if v, ok := got["name"]; ok {
+		t.Errorf(`got["name"] = %q, want unchanged flag "name" not written`, v)
+	}
+}
+
+func TestWriteFlagSetConfigIgnoresFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("config", "config.txt", "path to config file")
+	fs.String("name", "alice", "the name")
+
+	var buf bytes.Buffer
+	WriteFlagSetConfig(&buf, fs, "config")
+
+	if strings.Contains(buf.String(), "# config\n") {
+		t.Errorf("expected ignored flag %q to not be documented, got:\n%s", "config", buf.String())
+	}
+}
+
+func TestEnvWriterRoundTrip(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "the name")
+	*name = "alice # not a comment"
+
+	var buf bytes.Buffer
+	EnvWriter(&buf, fs)
+
+	got, err := parseAll(t, EnvParser, buf.String())
+	if err != nil {
+		t.Fatalf("re-parsing written env: %v", err)
+	}
+	if got["name"] != "alice # not a comment" {
+		t.Errorf(`got["name"] = %q, want %q`, got["name"], "alice # not a comment")
+	}
+}
+
+func TestJSONWriterRoundTrip(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "the name")
+	*name = "alice"
+	port := fs.Int("port", 8080, "the port")
+	*port = 9090
+
+	var buf bytes.Buffer
+	JSONWriter(&buf, fs)
+
+	got, err := parseAll(t, JSONParser, buf.String())
+	if err != nil {
+		t.Fatalf("re-parsing written json: %v", err)
+	}
+	if got["name"] != "alice" || got["port"] != "9090" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestParseArgsWriteConfigToFile(t *testing.T) {
+	dir := t.TempDir()
+	outPath := dir + "/out.txt"
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := fs.String("name", "", "the name")
+	*name = "alice"
+	o := NewDefaultOptions()
+	o.Path = dir + "/missing.txt"
+
+	err := ParseArgs(fs, o, []string{"-write-config=" + outPath})
+	if err != ErrWriteConfig {
+		t.Fatalf("ParseArgs: got err %v, want ErrWriteConfig", err)
+	}
+
+	b, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("reading written config file: %v", err)
+	}
+	got, err := parseAll(t, FlagParser, string(b))
+	if err != nil {
+		t.Fatalf("re-parsing written config: %v", err)
+	}
+	if got["name"] != "alice" {
+		t.Errorf(`got["name"] = %q, want "alice"`, got["name"])
+	}
+}
+
+func TestParseArgsWriteConfigFalseDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir("..") })
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "the name")
+	o := NewDefaultOptions()
+	o.Path = dir + "/missing.txt"
+
+	err := ParseArgs(fs, o, []string{"-write-config=false"})
+	if err != nil {
+		t.Fatalf("ParseArgs: got err %v, want nil (write-config=false should be a no-op)", err)
+	}
+	if _, err := os.Stat(dir + "/false"); !os.IsNotExist(err) {
+		t.Errorf("write-config=false created a file named %q, want no file written", "false")
+	}
+}